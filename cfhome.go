@@ -0,0 +1,67 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/cloudfoundry/cli/cf/configuration/config_helpers"
+)
+
+//cloneCFHome copies the logged-in CF CLI config into a fresh, private CF_HOME
+//directory. Each parallel worker targets its own clone instead of the shared
+//global config that repo.conn's RPC calls read and write, so concurrent
+//`cf target` calls across goroutines can't race each other.
+func cloneCFHome() (string, error) {
+	data, err := ioutil.ReadFile(config_helpers.DefaultFilePath())
+	if err != nil {
+		return "", err
+	}
+
+	home, err := ioutil.TempDir("", "cf-seed-home-")
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(filepath.Join(home, ".cf"), 0700); err != nil {
+		os.RemoveAll(home)
+		return "", err
+	}
+	if err := ioutil.WriteFile(cfHomeConfigFile(home), data, 0600); err != nil {
+		os.RemoveAll(home)
+		return "", err
+	}
+	return home, nil
+}
+
+//cfHomeConfigFile is the config.json a cloned CF_HOME carries its target in
+func cfHomeConfigFile(cfHome string) string {
+	return filepath.Join(cfHome, ".cf", "config.json")
+}
+
+//cfTargeted clones an isolated CF_HOME and targets it at org/space, returning
+//the CF_HOME for subsequent cfExec calls. Callers must os.RemoveAll it once
+//done.
+func cfTargeted(orgName, spaceName string) (string, error) {
+	home, err := cloneCFHome()
+	if err != nil {
+		return "", err
+	}
+	if err := cfExec(home, "target", "-o", orgName, "-s", spaceName); err != nil {
+		os.RemoveAll(home)
+		return "", err
+	}
+	return home, nil
+}
+
+//cfExec runs a cf CLI subcommand against the given isolated CF_HOME instead
+//of routing it through repo.conn, so concurrent workers never share
+//process-global target state
+func cfExec(cfHome string, args ...string) error {
+	cmd := exec.Command("cf", args...)
+	cmd.Env = append(os.Environ(), "CF_HOME="+cfHome)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
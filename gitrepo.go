@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+//resolveRepoCredential resolves app.RepoCredentials to the secret it names,
+//so a manifest references where a secret lives ("env:NAME" or "file:/path")
+//rather than inlining it
+func resolveRepoCredential(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, "env:"):
+		name := strings.TrimPrefix(ref, "env:")
+		value := os.Getenv(name)
+		if value == "" {
+			return "", fmt.Errorf("repo_credentials env var '%s' is not set", name)
+		}
+		return value, nil
+	case strings.HasPrefix(ref, "file:"):
+		path := strings.TrimPrefix(ref, "file:")
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	default:
+		return "", fmt.Errorf("repo_credentials must be 'env:VAR' or 'file:PATH', got '%s'", ref)
+	}
+}
+
+//gitAuth is everything syncAppRepo needs to authenticate a clone/fetch,
+//entirely through the environment so the secret never gets written into the
+//manifest, argv (visible to anyone on the host via ps/proc), or a persisted
+//.git/config. cleanup removes any temp files it created (e.g. an SSH
+//private key) and must be called once the git commands are done.
+type gitAuth struct {
+	env     []string
+	cleanup func()
+}
+
+//prepareGitAuth resolves app.RepoCredentials per app.RepoAuth ("ssh_key",
+//"basic", or "token") into the form git itself understands, following the
+//same auth-config-on-pull pattern Docker's plugin backend uses for Pull:
+//credentials are resolved once up front and threaded through as environment
+//rather than ever being written into the manifest or a persisted git config
+func prepareGitAuth(app deployApp) (*gitAuth, error) {
+	if app.RepoAuth == "" {
+		return &gitAuth{cleanup: func() {}}, nil
+	}
+
+	secret, err := resolveRepoCredential(app.RepoCredentials)
+	if err != nil {
+		return nil, err
+	}
+
+	switch app.RepoAuth {
+	case "ssh_key":
+		keyFile, err := ioutil.TempFile("", "cf-seed-repo-key-")
+		if err != nil {
+			return nil, err
+		}
+		keyPath := keyFile.Name()
+		keyFile.Close()
+		if err := ioutil.WriteFile(keyPath, []byte(secret), 0600); err != nil {
+			os.Remove(keyPath)
+			return nil, err
+		}
+		return &gitAuth{
+			env:     []string{"GIT_SSH_COMMAND=ssh -i " + keyPath + " -o StrictHostKeyChecking=no -o IdentitiesOnly=yes"},
+			cleanup: func() { os.Remove(keyPath) },
+		}, nil
+
+	case "token":
+		return &gitAuth{env: extraHeaderEnv("Authorization: Bearer " + secret), cleanup: func() {}}, nil
+
+	case "basic":
+		username, password := splitBasicCredential(secret)
+		encoded := base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+		return &gitAuth{env: extraHeaderEnv("Authorization: Basic " + encoded), cleanup: func() {}}, nil
+
+	default:
+		return nil, fmt.Errorf("app '%s' has unknown repo_auth '%s'", app.Name, app.RepoAuth)
+	}
+}
+
+//extraHeaderEnv sets http.extraHeader for this invocation only, through
+//git's GIT_CONFIG_COUNT/KEY/VALUE environment variables rather than a `-c`
+//argv flag (visible to any other process on the host) or rewriting the
+//remote URL (which git would persist into apps/<app>/.git/config)
+func extraHeaderEnv(header string) []string {
+	return []string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.extraHeader",
+		"GIT_CONFIG_VALUE_0=" + header,
+	}
+}
+
+//splitBasicCredential splits an "env:"/"file:"-resolved "username:password"
+//secret into its two halves
+func splitBasicCredential(secret string) (username, password string) {
+	parts := strings.SplitN(secret, ":", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return parts[0], ""
+}
+
+//resolveFetchedRef fetches every advertised branch and tag from origin and
+//resolves ref against what landed locally, trying ref as a branch, a tag,
+//and (for a bare commit SHA reachable from some branch/tag tip) a commit
+//name in turn. It returns the resolved local ref name and true on success.
+//
+//A direct `fetch origin <ref>` for a commit SHA only works when the git
+//server has unadvertised-object fetching enabled
+//(uploadpack.allowReachableSHA1InWant/allowAnySHA1InWant), which many
+//self-hosted servers operators point CF at (Gitea, older GitLab CE,
+//git-daemon, Bitbucket Server) don't turn on by default. Fetching the
+//advertised ref namespaces up front and resolving locally works against
+//those servers too, since the SHA only needs to be reachable from an
+//advertised branch or tag tip, not separately nameable on the remote.
+func resolveFetchedRef(runGit func(dir string, args ...string) error, dir, ref string) (string, bool) {
+	if err := runGit(dir, "fetch", "origin", "+refs/heads/*:refs/remotes/origin/*", "+refs/tags/*:refs/tags/*"); err != nil {
+		return "", false
+	}
+
+	candidates := []string{"refs/remotes/origin/" + ref, "refs/tags/" + ref, ref}
+	for _, candidate := range candidates {
+		if err := runGit(dir, "rev-parse", "--verify", "--quiet", candidate+"^{commit}"); err == nil {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+//syncAppRepo ensures appPath holds app.Repo checked out at app.RepoRef
+//(HEAD if unset), authenticating per app.RepoAuth/app.RepoCredentials.
+//
+//A fresh appPath is `clone --no-checkout`, resolved to the requested ref via
+//resolveFetchedRef, then `checkout --detach`-ed onto it. An appPath left
+//over from a prior seed has its origin re-pointed at app.Repo (in case the
+//manifest changed it), then is resolved and `reset --hard` onto the ref
+//instead of being skipped, so re-seeding the same manifest picks up new
+//commits on a moving ref.
+//
+//If resolveFetchedRef can't resolve ref locally (e.g. it names an
+//unreachable commit the server would need unadvertised-object fetching to
+//serve), syncAppRepo falls back to a direct `fetch origin <ref>` and
+//FETCH_HEAD, which is the only way to reach such a commit at all.
+func syncAppRepo(app deployApp, appPath string) error {
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		return err
+	}
+
+	auth, err := prepareGitAuth(app)
+	if err != nil {
+		return err
+	}
+	defer auth.cleanup()
+
+	ref := app.RepoRef
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	runGit := func(dir string, args ...string) error {
+		cmd := exec.Command(gitPath, args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), auth.env...)
+		return cmd.Run()
+	}
+
+	checkoutTarget := func(dir string) (string, error) {
+		if resolved, ok := resolveFetchedRef(runGit, dir, ref); ok {
+			return resolved, nil
+		}
+		if err := runGit(dir, "fetch", "origin", ref); err != nil {
+			return "", err
+		}
+		return "FETCH_HEAD", nil
+	}
+
+	files, err := ioutil.ReadDir(appPath)
+	if err != nil {
+		return err
+	}
+
+	if len(files) == 0 {
+		if err := runGit("", "clone", "--no-checkout", app.Repo, appPath); err != nil {
+			return err
+		}
+		target, err := checkoutTarget(appPath)
+		if err != nil {
+			return err
+		}
+		return runGit(appPath, "checkout", "--detach", target)
+	}
+
+	if err := runGit(appPath, "remote", "set-url", "origin", app.Repo); err != nil {
+		return err
+	}
+	target, err := checkoutTarget(appPath)
+	if err != nil {
+		return err
+	}
+	return runGit(appPath, "reset", "--hard", target)
+}
@@ -0,0 +1,65 @@
+package main
+
+import "fmt"
+
+//lintManifest checks a parsed manifest for problems worth catching before
+//touching a live foundation: duplicate org/space/app names, apps missing
+//exactly one of repo/path, service_access declared without the service_broker
+//that setAppAsService requires to act on it, and service brokers with empty
+//credentials. It collects every problem it finds rather than stopping at
+//the first, the same way multiError aggregates independent job failures.
+//
+//Note on service_access: there's no manifest construct for naming a broker
+//declared elsewhere and referencing it by name - ServiceAccess is only ever
+//registered against the same app's own singular ServiceBroker field
+//(setAppAsService). So the check below is "service_access requires a
+//service_broker on this same app", not "service_access resolves to some
+//broker declared earlier in the manifest" - the schema has no cross-broker
+//reference to validate.
+func lintManifest(manifest SeederManifest) error {
+	var errs multiError
+
+	seenOrgs := map[string]bool{}
+
+	for _, org := range manifest.Organizations {
+		if seenOrgs[org.Name] {
+			errs.Add(fmt.Errorf("duplicate organization name '%s'", org.Name))
+		}
+		seenOrgs[org.Name] = true
+
+		seenSpaces := map[string]bool{}
+		for _, space := range org.Spaces {
+			if seenSpaces[space.Name] {
+				errs.Add(fmt.Errorf("duplicate space name '%s' in organization '%s'", space.Name, org.Name))
+			}
+			seenSpaces[space.Name] = true
+
+			seenApps := map[string]bool{}
+			for _, app := range space.Apps {
+				if seenApps[app.Name] {
+					errs.Add(fmt.Errorf("duplicate app name '%s' in space '%s/%s'", app.Name, org.Name, space.Name))
+				}
+				seenApps[app.Name] = true
+
+				if (app.Repo == "") == (app.Path == "") {
+					errs.Add(fmt.Errorf("app '%s' must set exactly one of 'repo' or 'path'", app.Name))
+				}
+
+				emptyBroker := ServiceBroker{}
+				if app.ServiceBroker != emptyBroker {
+					if app.ServiceBroker.Username == "" || app.ServiceBroker.Password == "" {
+						errs.Add(fmt.Errorf("app '%s' service_broker must set both username and password", app.Name))
+					}
+				} else if len(app.ServiceAccess) > 0 {
+					//setAppAsService only calls enableServiceAccess for an app's own
+					//service_broker (seed.go's deployAndBindApp/setAppAsService), so
+					//service_access on an app with no service_broker is silently
+					//never applied
+					errs.Add(fmt.Errorf("app '%s' declares service_access but no service_broker to register it under", app.Name))
+				}
+			}
+		}
+	}
+
+	return errs.ErrorOrNil()
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+//multiError aggregates independent job failures so one failing org/space/app
+//doesn't stop its unrelated siblings from finishing
+type multiError struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (m *multiError) Add(err error) {
+	if err == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errs = append(m.errs, err)
+}
+
+//ErrorOrNil returns nil if nothing failed, the lone error if exactly one job
+//failed, or a combined error listing every failure otherwise
+func (m *multiError) ErrorOrNil() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	switch len(m.errs) {
+	case 0:
+		return nil
+	case 1:
+		return m.errs[0]
+	}
+	msgs := make([]string, len(m.errs))
+	for i, err := range m.errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Errorf("%d errors occurred:\n\t%s", len(m.errs), strings.Join(msgs, "\n\t"))
+}
+
+//jobPool bounds the number of concurrent Cloud Foundry CLI invocations to a
+//configured parallelism (the -p flag) while letting org/space/app work fan
+//out into it from any depth.
+//
+//Go schedules cheap coordination work (iterating orgs/spaces to discover
+//what to seed next) that never blocks on the pool itself, so it never holds
+//a slot. Limit (and LimitSync, on the calling goroutine) runs the actual CLI
+//invocations and only these consume a slot, which is what keeps recursive
+//fan-out (org -> space -> app) from deadlocking when parallelism is 1.
+type jobPool struct {
+	sem  chan struct{}
+	wg   sync.WaitGroup
+	errs multiError
+}
+
+func newJobPool(parallelism int) *jobPool {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	return &jobPool{sem: make(chan struct{}, parallelism)}
+}
+
+//Go runs fn in its own goroutine without waiting for a slot; use it for
+//fan-out/coordination, not for CLI calls
+func (p *jobPool) Go(fn func() error) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.errs.Add(fn())
+	}()
+}
+
+//Limit runs fn once a slot is free, bounding concurrent CLI invocations to
+//the pool's parallelism
+func (p *jobPool) Limit(fn func() error) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.sem <- struct{}{}
+		defer func() { <-p.sem }()
+		p.errs.Add(fn())
+	}()
+}
+
+//Wait blocks until every job scheduled so far - including jobs scheduled by
+//other jobs - has finished, then returns the aggregated error, if any
+func (p *jobPool) Wait() error {
+	p.wg.Wait()
+	return p.errs.ErrorOrNil()
+}
+
+//LimitSync runs fn on the calling goroutine once a slot is free, for the rare
+//case a coordination job needs a CLI call's result (e.g. an isolated CF_HOME)
+//before it can schedule its children. The caller is responsible for adding
+//itself to the pool (via Go) first.
+func (p *jobPool) LimitSync(fn func() error) error {
+	p.sem <- struct{}{}
+	defer func() { <-p.sem }()
+	return fn()
+}
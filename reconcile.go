@@ -0,0 +1,388 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry/cli/cf/api/resources"
+)
+
+//apply reconciles live foundation state with the manifest instead of blindly
+//re-issuing create-* calls. When plan is true no CliCommand is executed and the
+//planned actions are printed instead, mirroring a declarative `apply -plan` loop.
+//
+//Deleting orgs/spaces/services/apps that exist on the foundation but aren't
+//named in the manifest (prune) only happens when prune is explicitly set: a
+//manifest that seeds a handful of orgs has no way to know about every other
+//tenant's orgs/spaces/apps on a shared foundation, so treating "not in this
+//manifest" as "delete it" by default would be destructive to anyone else's
+//state there.
+func (repo *SeedRepo) apply(plan, prune bool) error {
+	if err := repo.applyOrganizations(plan, prune); err != nil {
+		return err
+	}
+	if err := repo.applySpaces(plan, prune); err != nil {
+		return err
+	}
+	if err := repo.applyServices(plan, prune); err != nil {
+		return err
+	}
+	if err := repo.applyApps(plan, prune); err != nil {
+		return err
+	}
+	return nil
+}
+
+//planOrExec either prints the action (in -plan mode) or runs it through
+//repo.run, so -n/dry-run suppresses apply's CliCommand calls the same way
+//it does for the plain seed/cleanup paths
+func (repo *SeedRepo) planOrExec(plan bool, description string, args ...string) error {
+	if plan {
+		fmt.Println("plan:", description)
+		return nil
+	}
+	return repo.run(args...)
+}
+
+func (repo *SeedRepo) applyOrganizations(plan, prune bool) error {
+	existing, err := repo.existingOrganizationNames()
+	if err != nil {
+		return err
+	}
+
+	wanted := map[string]bool{}
+	for _, org := range repo.Manifest.Organizations {
+		wanted[org.Name] = true
+		if existing[org.Name] {
+			continue
+		}
+		if err := repo.planOrExec(plan, "create-org "+org.Name, "create-org", org.Name); err != nil {
+			return err
+		}
+	}
+
+	if !prune {
+		return nil
+	}
+	for name := range existing {
+		if wanted[name] {
+			continue
+		}
+		if err := repo.planOrExec(plan, "delete-org "+name, "delete-org", name, "-f"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (repo *SeedRepo) applySpaces(plan, prune bool) error {
+	for _, org := range repo.Manifest.Organizations {
+		existing, err := repo.existingSpaceNames(org.Name)
+		if err != nil {
+			return err
+		}
+
+		wanted := map[string]bool{}
+		for _, space := range org.Spaces {
+			wanted[space.Name] = true
+			if existing[space.Name] {
+				continue
+			}
+			desc := fmt.Sprintf("create-space %s -o %s", space.Name, org.Name)
+			if err := repo.planOrExec(plan, desc, "create-space", space.Name, "-o", org.Name); err != nil {
+				return err
+			}
+		}
+
+		if !prune {
+			continue
+		}
+		for name := range existing {
+			if wanted[name] {
+				continue
+			}
+			desc := fmt.Sprintf("delete-space %s -o %s", name, org.Name)
+			if err := repo.planOrExec(plan, desc, "delete-space", name, "-o", org.Name, "-f"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (repo *SeedRepo) applyServices(plan, prune bool) error {
+	for _, org := range repo.Manifest.Organizations {
+		for _, space := range org.Spaces {
+			if !plan {
+				repo.run("target", "-o", org.Name, "-s", space.Name)
+			}
+
+			existing, err := repo.existingServiceInstanceNames(org.Name, space.Name)
+			if err != nil {
+				return err
+			}
+
+			wanted := map[string]bool{}
+			for _, service := range space.Services {
+				wanted[service.Name] = true
+				if existing[service.Name] {
+					continue
+				}
+				desc := fmt.Sprintf("create-service %s %s %s", service.Service, service.Plan, service.Name)
+				if err := repo.planOrExec(plan, desc, "create-service", service.Service, service.Plan, service.Name); err != nil {
+					return err
+				}
+			}
+
+			if !prune {
+				continue
+			}
+			for name := range existing {
+				if wanted[name] {
+					continue
+				}
+				if err := repo.pruneServiceInstance(plan, space, name); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+//pruneServiceInstance unbinds name from every app the current manifest
+//still declares it bound to before deleting it, the same unbind-before-
+//delete order unbindAppServices/deleteServices use, since CF refuses to
+//delete a service instance with live bindings.
+//
+//Note: if an app and a service it's bound to are both removed from the
+//manifest in the same -apply -prune run, applyApps hasn't deleted that app
+//yet at this point, but this loop only consults the manifest's current app
+//list, so it won't unbind an app that's also being pruned. delete-service
+//will then fail on CF's "instance has bindings" guard; remove the binding
+//(or the app) in a prior run before pruning the service.
+func (repo *SeedRepo) pruneServiceInstance(plan bool, space Space, name string) error {
+	for _, app := range space.Apps {
+		for _, bound := range app.Services {
+			if bound != name {
+				continue
+			}
+			if err := repo.planOrExec(plan, "unbind-service "+app.Name+" "+name, "unbind-service", app.Name, name); err != nil {
+				return err
+			}
+		}
+	}
+	return repo.planOrExec(plan, "delete-service "+name, "delete-service", name, "-f")
+}
+
+func (repo *SeedRepo) applyApps(plan, prune bool) error {
+	for _, org := range repo.Manifest.Organizations {
+		for _, space := range org.Spaces {
+			if !plan {
+				repo.run("target", "-o", org.Name, "-s", space.Name)
+			}
+
+			existing, err := repo.existingAppNames(org.Name, space.Name)
+			if err != nil {
+				return err
+			}
+
+			wanted := map[string]bool{}
+			for _, app := range space.Apps {
+				wanted[app.Name] = true
+				if existing[app.Name] {
+					//already pushed: still re-apply its declared services/env,
+					//since the manifest may have gained a binding or env var
+					//since this app was first created
+					if plan {
+						fmt.Println("plan: bind/env", app.Name)
+						continue
+					}
+					if err := repo.bindAppServicesAndEnvTargeted(app); err != nil {
+						return err
+					}
+					continue
+				}
+				if plan {
+					fmt.Println("plan: push", app.Name)
+					continue
+				}
+				if err := repo.deployApp(app); err != nil {
+					return err
+				}
+				if err := repo.bindAppServicesAndEnvTargeted(app); err != nil {
+					return err
+				}
+			}
+
+			if !prune {
+				continue
+			}
+			for name := range existing {
+				if wanted[name] {
+					continue
+				}
+				if err := repo.planOrExec(plan, "delete "+name, "delete", name, "-f", "-r"); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+//bindAppServicesAndEnvTargeted is bindAppServicesAndEnv's counterpart for
+//the apply path: applyApps already targets org/space on repo.conn (there's
+//no per-space isolated CF_HOME to push through like createApps has), so
+//this binds/sets-env/restages through repo.run instead of repo.runHome
+func (repo *SeedRepo) bindAppServicesAndEnvTargeted(app deployApp) error {
+	changed := false
+
+	for _, service := range app.Services {
+		if err := repo.run("bind-service", app.Name, service); err != nil {
+			return err
+		}
+		changed = true
+	}
+
+	for key, value := range app.Env {
+		if err := repo.run("set-env", app.Name, key, value); err != nil {
+			return err
+		}
+		changed = true
+	}
+
+	if changed {
+		if err := repo.run("restage", app.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//existingOrganizationNames inventories live orgs via the same
+//CliCommandWithoutTerminalOutput + json.Unmarshal pattern as findAppGUID
+func (repo *SeedRepo) existingOrganizationNames() (map[string]bool, error) {
+	output, err := repo.conn.CliCommandWithoutTerminalOutput("curl", "/v2/organizations")
+	if err != nil {
+		return nil, err
+	}
+	res := &resources.PaginatedOrganizationResources{}
+	if err := json.Unmarshal([]byte(strings.Join(output, "")), &res); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, org := range res.Resources {
+		names[org.Entity.Name] = true
+	}
+	return names, nil
+}
+
+func (repo *SeedRepo) existingSpaceNames(orgName string) (map[string]bool, error) {
+	output, err := repo.conn.CliCommandWithoutTerminalOutput("curl", fmt.Sprintf("/v2/organizations?q=name:%v", orgName))
+	if err != nil {
+		return nil, err
+	}
+	orgs := &resources.PaginatedOrganizationResources{}
+	if err := json.Unmarshal([]byte(strings.Join(output, "")), &orgs); err != nil {
+		return nil, err
+	}
+	if len(orgs.Resources) == 0 {
+		return map[string]bool{}, nil
+	}
+
+	output, err = repo.conn.CliCommandWithoutTerminalOutput("curl", fmt.Sprintf("/v2/organizations/%s/spaces", orgs.Resources[0].Metadata.Guid))
+	if err != nil {
+		return nil, err
+	}
+	res := &resources.PaginatedSpaceResources{}
+	if err := json.Unmarshal([]byte(strings.Join(output, "")), &res); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, space := range res.Resources {
+		names[space.Entity.Name] = true
+	}
+	return names, nil
+}
+
+func (repo *SeedRepo) existingServiceInstanceNames(orgName, spaceName string) (map[string]bool, error) {
+	spaceGUID, err := repo.findSpaceGUID(orgName, spaceName)
+	if err != nil {
+		return nil, err
+	}
+	if spaceGUID == "" {
+		return map[string]bool{}, nil
+	}
+
+	output, err := repo.conn.CliCommandWithoutTerminalOutput("curl", fmt.Sprintf("/v2/spaces/%s/service_instances", spaceGUID))
+	if err != nil {
+		return nil, err
+	}
+	res := &resources.PaginatedServiceInstanceResources{}
+	if err := json.Unmarshal([]byte(strings.Join(output, "")), &res); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, instance := range res.Resources {
+		names[instance.Entity.Name] = true
+	}
+	return names, nil
+}
+
+func (repo *SeedRepo) existingAppNames(orgName, spaceName string) (map[string]bool, error) {
+	spaceGUID, err := repo.findSpaceGUID(orgName, spaceName)
+	if err != nil {
+		return nil, err
+	}
+	if spaceGUID == "" {
+		return map[string]bool{}, nil
+	}
+
+	output, err := repo.conn.CliCommandWithoutTerminalOutput("curl", fmt.Sprintf("/v2/spaces/%s/apps", spaceGUID))
+	if err != nil {
+		return nil, err
+	}
+	res := &resources.PaginatedApplicationResources{}
+	if err := json.Unmarshal([]byte(strings.Join(output, "")), &res); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for _, app := range res.Resources {
+		names[app.Resource.Entity.Name] = true
+	}
+	return names, nil
+}
+
+//findSpaceGUID looks up a space's GUID by org+space name, returning "" if not found
+func (repo *SeedRepo) findSpaceGUID(orgName, spaceName string) (string, error) {
+	output, err := repo.conn.CliCommandWithoutTerminalOutput("curl", fmt.Sprintf("/v2/organizations?q=name:%v", orgName))
+	if err != nil {
+		return "", err
+	}
+	orgs := &resources.PaginatedOrganizationResources{}
+	if err := json.Unmarshal([]byte(strings.Join(output, "")), &orgs); err != nil {
+		return "", err
+	}
+	if len(orgs.Resources) == 0 {
+		return "", nil
+	}
+
+	output, err = repo.conn.CliCommandWithoutTerminalOutput("curl", fmt.Sprintf("/v2/organizations/%s/spaces?q=name:%v", orgs.Resources[0].Metadata.Guid, spaceName))
+	if err != nil {
+		return "", err
+	}
+	spaces := &resources.PaginatedSpaceResources{}
+	if err := json.Unmarshal([]byte(strings.Join(output, "")), &spaces); err != nil {
+		return "", err
+	}
+	if len(spaces.Resources) == 0 {
+		return "", nil
+	}
+	return spaces.Resources[0].Metadata.Guid, nil
+}
@@ -6,9 +6,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"os/exec"
+	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/cloudfoundry-community/cftype"
 	"github.com/cloudfoundry/cli/cf/api/resources"
@@ -35,6 +36,11 @@ type SeedPlugin struct{}
 
 //Run of seeder plugin
 func (plugin SeedPlugin) Run(cliConnection plugin.CliConnection, args []string) {
+	if len(args) > 0 && args[0] == "seed-lint" {
+		runSeedLint(args)
+		return
+	}
+
 	app := cli.NewApp()
 	app.Name = "seed"
 	app.Version = VERSION
@@ -51,6 +57,27 @@ func (plugin SeedPlugin) Run(cliConnection plugin.CliConnection, args []string)
 			Name:  "c",
 			Usage: "cleanup all things created by the manifest",
 		},
+		cli.BoolFlag{
+			Name:  "apply",
+			Usage: "reconcile the foundation with the manifest instead of blindly re-creating everything",
+		},
+		cli.BoolFlag{
+			Name:  "plan",
+			Usage: "with -apply, print the planned actions instead of executing them",
+		},
+		cli.BoolFlag{
+			Name:  "prune",
+			Usage: "with -apply, also delete orgs/spaces/services/apps that exist on the foundation but aren't in the manifest",
+		},
+		cli.IntFlag{
+			Name:  "p",
+			Value: runtime.NumCPU(),
+			Usage: "number of orgs/spaces/apps to seed in parallel",
+		},
+		cli.BoolFlag{
+			Name:  "n",
+			Usage: "print the cf commands that would run instead of running them (dry run)",
+		},
 	}
 	app.Action = func(c *cli.Context) {
 		if !c.IsSet("f") {
@@ -58,40 +85,99 @@ func (plugin SeedPlugin) Run(cliConnection plugin.CliConnection, args []string)
 			os.Exit(1)
 		}
 		fileName := c.String("f")
-		seedRepo := NewSeedRepo(cliConnection, fileName)
+		seedRepo := NewSeedRepo(cliConnection, fileName, c.Int("p"), c.Bool("n"))
 
 		err := seedRepo.readManifest()
 		fatalIf(err)
 
-		if c.Bool("c") {
+		if c.Bool("apply") {
+			err = seedRepo.apply(c.Bool("plan"), c.Bool("prune"))
+			fatalIf(err)
+		} else if c.Bool("c") {
+			err = seedRepo.unbindAppServices()
+			fatalIf(err)
+
 			err = seedRepo.deleteApps()
 			fatalIf(err)
 
 			err = seedRepo.deleteServices()
 			fatalIf(err)
 
+			err = seedRepo.unassignSpaceRoles()
+			fatalIf(err)
+
 			err = seedRepo.deleteSpaces()
 			fatalIf(err)
 
+			err = seedRepo.unassignOrgRoles()
+			fatalIf(err)
+
 			err = seedRepo.deleteOrganizations()
 			fatalIf(err)
+
+			err = seedRepo.deleteBuildpacks()
+			fatalIf(err)
+
+			err = seedRepo.deleteUsers()
+			fatalIf(err)
 		} else {
+			err = seedRepo.createBuildpacks()
+			fatalIf(err)
+
+			err = seedRepo.createUsers()
+			fatalIf(err)
+
 			err = seedRepo.createOrganizations()
 			fatalIf(err)
 
+			err = seedRepo.assignOrgRoles()
+			fatalIf(err)
+
 			err = seedRepo.createSpaces()
 			fatalIf(err)
 
-			err = seedRepo.createApps()
+			err = seedRepo.assignSpaceRoles()
 			fatalIf(err)
 
 			err = seedRepo.createServices()
 			fatalIf(err)
+
+			err = seedRepo.createApps()
+			fatalIf(err)
 		}
 	}
 	app.Run(args)
 }
 
+//runSeedLint is the seed-lint subcommand: it reads and validates a manifest
+//through lintManifest without issuing a single cf command, so operators get
+//fast feedback before touching a live foundation
+func runSeedLint(args []string) {
+	app := cli.NewApp()
+	app.Name = "seed-lint"
+	app.Usage = "Validates a seed manifest without touching a live foundation"
+	app.Flags = []cli.Flag{
+		cli.StringFlag{
+			Name:  "f",
+			Value: "",
+			Usage: "seed manifest to validate",
+		},
+	}
+	app.Action = func(c *cli.Context) {
+		if !c.IsSet("f") {
+			cli.ShowAppHelp(c)
+			os.Exit(1)
+		}
+
+		seedRepo := NewSeedRepo(nil, c.String("f"), 1, false)
+		fatalIf(seedRepo.readManifestStrict())
+		fatalIf(lintManifest(seedRepo.Manifest))
+
+		fmt.Println("manifest OK")
+	}
+	app.Run(args)
+}
+
 //GetMetadata of plugin
 func (SeedPlugin) GetMetadata() plugin.PluginMetadata {
 	versionParts := strings.Split(string(VERSION), ".")
@@ -111,22 +197,64 @@ func (SeedPlugin) GetMetadata() plugin.PluginMetadata {
 				Name:     "seed",
 				HelpText: "Seeds Cloud Foundry and setups apps/orgs/services on new Cloud Foundry setup",
 			},
+			{
+				Name:     "seed-lint",
+				HelpText: "Validates a seed manifest without touching a live foundation",
+			},
 		},
 	}
 }
 
 //SeedRepo of cli
 type SeedRepo struct {
-	conn     plugin.CliConnection
-	fileName string
-	Manifest SeederManifest
+	conn        plugin.CliConnection
+	fileName    string
+	Manifest    SeederManifest
+	Parallelism int
+	DryRun      bool
 }
 
-func NewSeedRepo(conn plugin.CliConnection, fileName string) *SeedRepo {
+func NewSeedRepo(conn plugin.CliConnection, fileName string, parallelism int, dryRun bool) *SeedRepo {
 	return &SeedRepo{
-		conn:     conn,
-		fileName: fileName,
+		conn:        conn,
+		fileName:    fileName,
+		Parallelism: parallelism,
+		DryRun:      dryRun,
+	}
+}
+
+//parallelism is the effective worker pool size; anything less than 1 (a -p
+//passed explicitly as 0 or below) still seeds with one worker rather than
+//spawning nothing
+func (repo *SeedRepo) parallelism() int {
+	if repo.Parallelism < 1 {
+		return 1
 	}
+	return repo.Parallelism
+}
+
+//run executes args through repo.conn.CliCommand, or - in -n/dry-run mode -
+//just prints the command, giving operators a preview of what a seed would do
+//before it touches a live foundation
+func (repo *SeedRepo) run(args ...string) error {
+	if repo.DryRun {
+		fmt.Println("dry-run:", strings.Join(args, " "))
+		return nil
+	}
+	_, err := repo.conn.CliCommand(args...)
+	return err
+}
+
+//runHome is run's counterpart for the parallel createApps/createServices
+//path: it executes args via cfExec against an isolated CF_HOME, or - in
+//-n/dry-run mode - just prints the command, the same as run does for calls
+//made through repo.conn
+func (repo *SeedRepo) runHome(cfHome string, args ...string) error {
+	if repo.DryRun {
+		fmt.Println("dry-run:", strings.Join(args, " "))
+		return nil
+	}
+	return cfExec(cfHome, args...)
 }
 
 func (repo *SeedRepo) readManifest() error {
@@ -144,9 +272,47 @@ func (repo *SeedRepo) readManifest() error {
 	return nil
 }
 
-func (repo *SeedRepo) createOrganizations() error {
-	for _, org := range repo.Manifest.Organizations {
-		_, err := repo.conn.CliCommand("create-org", org.Name)
+//readManifestStrict is readManifest with unknown-field detection, used by
+//seed-lint to catch manifest typos that the permissive `seed` path tolerates
+func (repo *SeedRepo) readManifestStrict() error {
+	file, err := ioutil.ReadFile(repo.fileName)
+	if err != nil {
+		return err
+	}
+	repo.Manifest = SeederManifest{}
+
+	return yaml.UnmarshalStrict(file, &repo.Manifest)
+}
+
+//createBuildpacks creates or updates every buildpack declared in the manifest
+func (repo *SeedRepo) createBuildpacks() error {
+	for _, buildpack := range repo.Manifest.Buildpacks {
+		if buildpack.Enabled != nil && buildpack.Disabled != nil && *buildpack.Enabled && *buildpack.Disabled {
+			return fmt.Errorf("Buildpack '%s' cannot be both enabled and disabled", buildpack.Name)
+		}
+
+		path := buildpack.Path
+		if path == "" {
+			path = buildpack.Url
+		}
+
+		//update-buildpack only takes BUILDPACK_NAME positionally; path and
+		//position are passed as -p/-i instead of reusing create-buildpack's
+		//positional args
+		var args []string
+		if repo.buildpackExists(buildpack.Name) {
+			args = []string{"update-buildpack", buildpack.Name, "-p", path, "-i", strconv.Itoa(buildpack.Position)}
+		} else {
+			args = []string{"create-buildpack", buildpack.Name, path, strconv.Itoa(buildpack.Position)}
+		}
+		if buildpack.Enabled != nil && *buildpack.Enabled {
+			args = append(args, "--enable")
+		}
+		if buildpack.Disabled != nil && *buildpack.Disabled {
+			args = append(args, "--disable")
+		}
+
+		err := repo.run(args...)
 		if err != nil {
 			return err
 		}
@@ -154,9 +320,10 @@ func (repo *SeedRepo) createOrganizations() error {
 	return nil
 }
 
-func (repo *SeedRepo) deleteOrganizations() error {
-	for _, org := range repo.Manifest.Organizations {
-		_, err := repo.conn.CliCommand("delete-org", org.Name, "-f")
+//deleteBuildpacks removes every buildpack declared in the manifest
+func (repo *SeedRepo) deleteBuildpacks() error {
+	for _, buildpack := range repo.Manifest.Buildpacks {
+		err := repo.run("delete-buildpack", buildpack.Name, "-f")
 		if err != nil {
 			return err
 		}
@@ -164,11 +331,89 @@ func (repo *SeedRepo) deleteOrganizations() error {
 	return nil
 }
 
-func (repo *SeedRepo) createSpaces() error {
+//buildpackExists reports whether a buildpack with the given name is already registered
+func (repo *SeedRepo) buildpackExists(name string) bool {
+	output, _ := repo.conn.CliCommandWithoutTerminalOutput("curl", fmt.Sprintf("/v2/buildpacks?q=name:%v", name))
+	res := &resources.PaginatedBuildpackResources{}
+	json.Unmarshal([]byte(strings.Join(output, "")), &res)
+	return len(res.Resources) > 0
+}
+
+//getUAAEndpoint reads the UAA endpoint from the same core_config repository getAppInfo uses,
+//failing fast so a missing `cf login` target doesn't surface as a confusing create-user error
+func (repo *SeedRepo) getUAAEndpoint() (string, error) {
+	confRepo := core_config.NewRepositoryFromFilepath(config_helpers.DefaultFilePath(), fatalIf)
+	endpoint := confRepo.UaaEndpoint()
+	if endpoint == "" {
+		return "", errors.New("UAA endpoint is not set, please target and login with `cf login` before seeding users")
+	}
+	return endpoint, nil
+}
+
+//createUsers creates every UAA user declared in the manifest
+func (repo *SeedRepo) createUsers() error {
+	if len(repo.Manifest.Users) == 0 {
+		return nil
+	}
+
+	if _, err := repo.getUAAEndpoint(); err != nil {
+		return err
+	}
+
+	for _, user := range repo.Manifest.Users {
+		//an origin-based (SSO) user has no local password, and cf create-user
+		//rejects PASSWORD combined with --origin
+		var args []string
+		if user.Origin != "" {
+			args = []string{"create-user", user.Username, "--origin", user.Origin}
+		} else {
+			args = []string{"create-user", user.Username, user.Password}
+		}
+		err := repo.run(args...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//deleteUsers removes every UAA user declared in the manifest
+func (repo *SeedRepo) deleteUsers() error {
+	for _, user := range repo.Manifest.Users {
+		err := repo.run("delete-user", user.Username, "-f")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//assignOrgRoles grants the declared org roles to their users, once the orgs
+//exist. set-org-role takes the org name as an explicit argument, so roles
+//across every org can be granted concurrently without any shared target state.
+func (repo *SeedRepo) assignOrgRoles() error {
+	pool := newJobPool(repo.parallelism())
 	for _, org := range repo.Manifest.Organizations {
-		repo.conn.CliCommand("target", "-o", org.Name)
-		for _, space := range org.Spaces {
-			_, err := repo.conn.CliCommand("create-space", space.Name)
+		org := org
+		pool.Go(func() error {
+			for _, role := range org.Roles {
+				role := role
+				pool.Limit(func() error {
+					err := repo.run("set-org-role", role.Username, org.Name, role.Role)
+					return err
+				})
+			}
+			return nil
+		})
+	}
+	return pool.Wait()
+}
+
+//unassignOrgRoles revokes the declared org roles from their users
+func (repo *SeedRepo) unassignOrgRoles() error {
+	for _, org := range repo.Manifest.Organizations {
+		for _, role := range org.Roles {
+			err := repo.run("unset-org-role", role.Username, org.Name, role.Role)
 			if err != nil {
 				return err
 			}
@@ -177,11 +422,98 @@ func (repo *SeedRepo) createSpaces() error {
 	return nil
 }
 
+//assignSpaceRoles grants the declared space roles to their users, once the
+//spaces exist. set-space-role takes org and space as explicit arguments, so
+//roles across every org/space can be granted concurrently.
+func (repo *SeedRepo) assignSpaceRoles() error {
+	pool := newJobPool(repo.parallelism())
+	for _, org := range repo.Manifest.Organizations {
+		org := org
+		pool.Go(func() error {
+			for _, space := range org.Spaces {
+				space := space
+				pool.Go(func() error {
+					for _, role := range space.Roles {
+						role := role
+						pool.Limit(func() error {
+							err := repo.run("set-space-role", role.Username, org.Name, space.Name, role.Role)
+							return err
+						})
+					}
+					return nil
+				})
+			}
+			return nil
+		})
+	}
+	return pool.Wait()
+}
+
+//unassignSpaceRoles revokes the declared space roles from their users
+func (repo *SeedRepo) unassignSpaceRoles() error {
+	for _, org := range repo.Manifest.Organizations {
+		for _, space := range org.Spaces {
+			for _, role := range space.Roles {
+				err := repo.run("unset-space-role", role.Username, org.Name, space.Name, role.Role)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+//createOrganizations creates every org declared in the manifest, in parallel
+func (repo *SeedRepo) createOrganizations() error {
+	pool := newJobPool(repo.parallelism())
+	for _, org := range repo.Manifest.Organizations {
+		org := org
+		pool.Limit(func() error {
+			err := repo.run("create-org", org.Name)
+			return err
+		})
+	}
+	return pool.Wait()
+}
+
+func (repo *SeedRepo) deleteOrganizations() error {
+	for _, org := range repo.Manifest.Organizations {
+		err := repo.run("delete-org", org.Name, "-f")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//createSpaces creates every space declared in the manifest, in parallel.
+//create-space takes the owning org as an explicit -o argument rather than
+//relying on a prior `cf target`, so spaces across every org can be created
+//concurrently.
+func (repo *SeedRepo) createSpaces() error {
+	pool := newJobPool(repo.parallelism())
+	for _, org := range repo.Manifest.Organizations {
+		org := org
+		pool.Go(func() error {
+			for _, space := range org.Spaces {
+				space := space
+				pool.Limit(func() error {
+					err := repo.run("create-space", space.Name, "-o", org.Name)
+					return err
+				})
+			}
+			return nil
+		})
+	}
+	return pool.Wait()
+}
+
 func (repo *SeedRepo) deleteSpaces() error {
 	for _, org := range repo.Manifest.Organizations {
-		repo.conn.CliCommand("target", "-o", org.Name)
+		repo.run("target", "-o", org.Name)
 		for _, space := range org.Spaces {
-			_, err := repo.conn.CliCommand("delete-space", space.Name, "-f")
+			err := repo.run("delete-space", space.Name, "-f")
 			if err != nil {
 				return err
 			}
@@ -190,14 +522,77 @@ func (repo *SeedRepo) deleteSpaces() error {
 	return nil
 }
 
+//createServices creates every service instance declared in the manifest.
+//create-service only resolves its space from the current target, so each
+//space gets its own isolated CF_HOME to target, letting orgs, spaces, and the
+//services within a space all be created concurrently.
 func (repo *SeedRepo) createServices() error {
+	pool := newJobPool(repo.parallelism())
+	for _, org := range repo.Manifest.Organizations {
+		org := org
+		pool.Go(func() error {
+			for _, space := range org.Spaces {
+				space := space
+				pool.Go(func() error {
+					return repo.createServicesInSpace(pool, org.Name, space)
+				})
+			}
+			return nil
+		})
+	}
+	return pool.Wait()
+}
+
+//createServicesInSpace targets an isolated CF_HOME at org/space and fans the
+//space's services back out onto pool so they're created concurrently too
+func (repo *SeedRepo) createServicesInSpace(pool *jobPool, orgName string, space Space) error {
+	if len(space.Services) == 0 {
+		return nil
+	}
+
+	var home string
+	err := pool.LimitSync(func() error {
+		h, err := cfTargeted(orgName, space.Name)
+		home = h
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, service := range space.Services {
+		service := service
+		wg.Add(1)
+		pool.Limit(func() error {
+			defer wg.Done()
+			return repo.runHome(home, "create-service", service.Service, service.Plan, service.Name)
+		})
+	}
+
+	//block here, not in a detached goroutine: this call is already running
+	//inside a pool.Go job that holds no semaphore slot, so waiting on our own
+	//services can't deadlock the pool, and it guarantees home - which holds a
+	//copy of the operator's CF auth token - is removed before the process can exit
+	wg.Wait()
+	os.RemoveAll(home)
+	return nil
+}
+
+//unbindAppServices unbinds every app's declared service bindings. It must
+//run before deleteApps, since CF refuses to delete a service instance while
+//bindings still exist (see CloudControllerServiceRepository.DeleteService)
+//but there's no way to unbind an app from a service once the app is gone.
+func (repo *SeedRepo) unbindAppServices() error {
 	for _, org := range repo.Manifest.Organizations {
 		for _, space := range org.Spaces {
-			repo.conn.CliCommand("target", "-o", org.Name, "-s", space.Name)
-			for _, service := range space.Services {
-				_, err := repo.conn.CliCommand("create-service", service.Service, service.Plan, service.Name)
-				if err != nil {
-					return err
+			repo.run("target", "-o", org.Name, "-s", space.Name)
+			for _, app := range space.Apps {
+				for _, service := range app.Services {
+					err := repo.run("unbind-service", app.Name, service)
+					if err != nil {
+						return err
+					}
 				}
 			}
 		}
@@ -205,12 +600,14 @@ func (repo *SeedRepo) createServices() error {
 	return nil
 }
 
+//deleteServices removes every service instance declared in the manifest.
+//It must run after deleteApps/unbindAppServices so no bindings remain.
 func (repo *SeedRepo) deleteServices() error {
 	for _, org := range repo.Manifest.Organizations {
 		for _, space := range org.Spaces {
-			repo.conn.CliCommand("target", "-o", org.Name, "-s", space.Name)
+			repo.run("target", "-o", org.Name, "-s", space.Name)
 			for _, service := range space.Services {
-				_, err := repo.conn.CliCommand("delete-service", service.Name, "-f")
+				err := repo.run("delete-service", service.Name, "-f")
 				if err != nil {
 					return err
 				}
@@ -220,25 +617,111 @@ func (repo *SeedRepo) deleteServices() error {
 	return nil
 }
 
+//bindAppServicesAndEnv binds the app's declared services, sets its declared
+//env vars, and restages once if any bindings or env vars were applied so the
+//app picks them up. It runs against the space's isolated CF_HOME (via
+//cfExec) rather than repo.conn, since every space pushed by createApps
+//targets its own clone.
+func (repo *SeedRepo) bindAppServicesAndEnv(cfHome string, app deployApp) error {
+	changed := false
+
+	for _, service := range app.Services {
+		if err := repo.runHome(cfHome, "bind-service", app.Name, service); err != nil {
+			return err
+		}
+		changed = true
+	}
+
+	for key, value := range app.Env {
+		if err := repo.runHome(cfHome, "set-env", app.Name, key, value); err != nil {
+			return err
+		}
+		changed = true
+	}
+
+	if changed {
+		if err := repo.runHome(cfHome, "restage", app.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//createApps pushes every app declared in the manifest. Pushing dominates
+//seeding time, so orgs, spaces within an org, and apps within a space are all
+//dispatched through the shared worker pool (bounded by the -p flag) instead
+//of running one at a time. `cf push` only resolves its org/space from the
+//current target, so each space targets its own isolated CF_HOME rather than
+//the racy, process-global `cf target` the sequential version used.
 func (repo *SeedRepo) createApps() error {
+	pool := newJobPool(repo.parallelism())
 	for _, org := range repo.Manifest.Organizations {
-		for _, space := range org.Spaces {
-			repo.conn.CliCommand("target", "-o", org.Name, "-s", space.Name)
-			for _, app := range space.Apps {
-				err := repo.deployApp(app)
-				if err != nil {
-					return err
-				}
-				emptyServiceBroker := ServiceBroker{}
-				if app.ServiceBroker != emptyServiceBroker {
-					fmt.Println("setting app as service")
-					err := repo.setAppAsService(app)
-					if err != nil {
-						return err
-					}
-				}
+		org := org
+		pool.Go(func() error {
+			for _, space := range org.Spaces {
+				space := space
+				pool.Go(func() error {
+					return repo.createAppsInSpace(pool, org.Name, space)
+				})
 			}
-		}
+			return nil
+		})
+	}
+	return pool.Wait()
+}
+
+//createAppsInSpace targets an isolated CF_HOME at org/space and fans the
+//space's apps back out onto pool so they're pushed concurrently too
+func (repo *SeedRepo) createAppsInSpace(pool *jobPool, orgName string, space Space) error {
+	if len(space.Apps) == 0 {
+		return nil
+	}
+
+	var home string
+	err := pool.LimitSync(func() error {
+		h, err := cfTargeted(orgName, space.Name)
+		home = h
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for _, app := range space.Apps {
+		app := app
+		wg.Add(1)
+		pool.Limit(func() error {
+			defer wg.Done()
+			return repo.deployAndBindApp(home, app)
+		})
+	}
+
+	//block here, not in a detached goroutine: this call is already running
+	//inside a pool.Go job that holds no semaphore slot, so waiting on our own
+	//apps can't deadlock the pool, and it guarantees home - which holds a
+	//copy of the operator's CF auth token - is removed before the process can exit
+	wg.Wait()
+	os.RemoveAll(home)
+	return nil
+}
+
+//deployAndBindApp pushes a single app and binds its services/env against the
+//given isolated CF_HOME, then registers it as a service broker if declared
+//(see setAppAsService for which calls that makes through repo.conn)
+func (repo *SeedRepo) deployAndBindApp(cfHome string, app deployApp) error {
+	if err := repo.deployAppWithHome(cfHome, app); err != nil {
+		return err
+	}
+
+	if err := repo.bindAppServicesAndEnv(cfHome, app); err != nil {
+		return err
+	}
+
+	emptyServiceBroker := ServiceBroker{}
+	if app.ServiceBroker != emptyServiceBroker {
+		fmt.Println("setting app as service")
+		return repo.setAppAsService(cfHome, app)
 	}
 	return nil
 }
@@ -246,7 +729,7 @@ func (repo *SeedRepo) createApps() error {
 func (repo *SeedRepo) deleteApps() error {
 	for _, org := range repo.Manifest.Organizations {
 		for _, space := range org.Spaces {
-			repo.conn.CliCommand("target", "-o", org.Name, "-s", space.Name)
+			repo.run("target", "-o", org.Name, "-s", space.Name)
 			for _, app := range space.Apps {
 				emptyServiceBroker := ServiceBroker{}
 				if app.ServiceBroker != emptyServiceBroker {
@@ -268,7 +751,7 @@ func (repo *SeedRepo) deleteApps() error {
 //DeleteApp deletes a single app
 func (repo *SeedRepo) deleteApp(app deployApp) error {
 
-	_, err := repo.conn.CliCommand("delete", app.Name, "-f", "-r")
+	err := repo.run("delete", app.Name, "-f", "-r")
 	if err != nil {
 		return err
 	}
@@ -284,17 +767,8 @@ func (repo *SeedRepo) deployApp(app deployApp) error {
 		appPath := wd + "/apps/" + app.Name
 		os.MkdirAll(appPath, 0777)
 
-		files, _ := ioutil.ReadDir(appPath)
-
-		if len(files) == 0 {
-			gitPath, err := exec.LookPath("git")
-			if err != nil {
-				return err
-			}
-			err = exec.Command(gitPath, "clone", app.Repo, appPath).Run()
-			if err != nil {
-				return nil
-			}
+		if err := syncAppRepo(app, appPath); err != nil {
+			return err
 		}
 		args = append(args, "-p", appPath)
 
@@ -327,13 +801,66 @@ func (repo *SeedRepo) deployApp(app deployApp) error {
 		args = append(args, "-f", app.Manifest)
 	}
 
-	repo.conn.CliCommand(args...)
+	repo.run(args...)
 
 	return nil
 }
 
-func (repo *SeedRepo) setAppAsService(app deployApp) error {
-	appInfo := repo.getAppInfo(app)
+//deployAppWithHome is deployApp run against an isolated CF_HOME (via cfExec)
+//instead of repo.conn, for the parallel createApps path where every space
+//has its own target
+func (repo *SeedRepo) deployAppWithHome(cfHome string, app deployApp) error {
+	args := []string{"push", app.Name}
+	if app.Repo != "" {
+		//appPath is rooted under cfHome, which is already unique per space, so
+		//two spaces pushing an app with the same name can't clobber each other's
+		//checkout the way a shared wd+"/apps/"+app.Name would under parallelism
+		appPath := cfHome + "/apps/" + app.Name
+		os.MkdirAll(appPath, 0777)
+
+		if err := syncAppRepo(app, appPath); err != nil {
+			return err
+		}
+		args = append(args, "-p", appPath)
+
+	} else if app.Path != "" {
+		args = append(args, "-p", app.Path)
+	} else {
+		errMsg := fmt.Sprintf("App need repo or path %s", app.Name)
+		return errors.New(errMsg)
+	}
+
+	if app.Disk != "" {
+		args = append(args, "-k", app.Disk)
+	}
+	if app.Memory != "" {
+		args = append(args, "-m", app.Memory)
+	}
+	if app.Instances != "" {
+		args = append(args, "-i", app.Instances)
+	}
+	if app.Hostname != "" {
+		args = append(args, "-n", app.Hostname)
+	}
+	if app.Domain != "" {
+		args = append(args, "-d", app.Domain)
+	}
+	if app.Buildpack != "" {
+		args = append(args, "-b", app.Buildpack)
+	}
+	if app.Manifest != "" {
+		args = append(args, "-f", app.Manifest)
+	}
+
+	return repo.runHome(cfHome, args...)
+}
+
+//setAppAsService registers app as a service broker. It reads the app's space
+//from the space's isolated CF_HOME, since every space pushed by createApps
+//targets its own clone; create-service-broker and enable-service-access then
+//run through repo.run on repo.conn
+func (repo *SeedRepo) setAppAsService(cfHome string, app deployApp) error {
+	appInfo := repo.getAppInfoWithHome(cfHome, app)
 	appRoute, err := repo.firstAppRoute(appInfo)
 	if err != nil {
 		return err
@@ -382,6 +909,18 @@ func (repo *SeedRepo) getAppInfo(app deployApp) *cftype.RetrieveAParticularApp {
 	return appInfo
 }
 
+//getAppInfoWithHome is getAppInfo read from an isolated CF_HOME's config
+//instead of the shared, process-global one, for the parallel createApps path
+func (repo *SeedRepo) getAppInfoWithHome(cfHome string, app deployApp) *cftype.RetrieveAParticularApp {
+	confRepo := core_config.NewRepositoryFromFilepath(cfHomeConfigFile(cfHome), fatalIf)
+	spaceGUID := confRepo.SpaceFields().Guid
+
+	appGUID := repo.findAppGUID(spaceGUID, app.Name)
+
+	appInfo := repo.findApp(appGUID)
+	return appInfo
+}
+
 func (repo *SeedRepo) firstAppRoute(app *cftype.RetrieveAParticularApp) (fullRoute string, err error) {
 	routes := &cftype.ListAllRoutesForTheApp{}
 	cmd := []string{"curl", app.Entity.RoutesURL}
@@ -425,13 +964,13 @@ func (repo *SeedRepo) findAppGUID(spaceGUID string, appName string) string {
 
 func (repo *SeedRepo) createServiceBroker(broker ServiceBroker) error {
 	args := []string{"create-service-broker", broker.Name, broker.Username, broker.Password, broker.Url}
-	_, err := repo.conn.CliCommand(args...)
+	err := repo.run(args...)
 	return err
 }
 
 func (repo *SeedRepo) deleteServiceBroker(broker ServiceBroker) error {
 	args := []string{"delete-service-broker", broker.Name, "-f"}
-	_, err := repo.conn.CliCommand(args...)
+	err := repo.run(args...)
 	return err
 }
 
@@ -443,7 +982,7 @@ func (repo *SeedRepo) enableServiceAccess(service Service) error {
 	if service.Org != "" {
 		args = append(args, "-o", service.Org)
 	}
-	_, err := repo.conn.CliCommand(args...)
+	err := repo.run(args...)
 	return err
 }
 
@@ -455,6 +994,6 @@ func (repo *SeedRepo) disableServiceAccess(service Service) error {
 	if service.Org != "" {
 		args = append(args, "-o", service.Org)
 	}
-	_, err := repo.conn.CliCommand(args...)
+	err := repo.run(args...)
 	return err
 }
@@ -0,0 +1,84 @@
+package main
+
+//SeederManifest top level manifest structure for seeding a Cloud Foundry
+type SeederManifest struct {
+	Buildpacks    []Buildpack    `yaml:"buildpacks"`
+	Users         []User         `yaml:"users"`
+	Organizations []Organization `yaml:"organizations"`
+}
+
+//User describes a UAA user to create; its org/space roles are granted through
+//the Roles entries of Organization and Space
+type User struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Origin   string `yaml:"origin"`
+}
+
+//Role grants a named user one of the CF org/space roles
+type Role struct {
+	Username string `yaml:"username"`
+	Role     string `yaml:"role"`
+}
+
+//Buildpack describes a single buildpack entry to be created/updated on the foundation
+type Buildpack struct {
+	Name     string `yaml:"name"`
+	Path     string `yaml:"path"`
+	Url      string `yaml:"url"`
+	Position int    `yaml:"position"`
+	Enabled  *bool  `yaml:"enabled"`
+	Disabled *bool  `yaml:"disabled"`
+}
+
+//Organization describes a single org, its roles, and the spaces within it
+type Organization struct {
+	Name   string  `yaml:"name"`
+	Roles  []Role  `yaml:"roles"`
+	Spaces []Space `yaml:"spaces"`
+}
+
+//Space describes a single space, its roles, and the services/apps within it
+type Space struct {
+	Name     string      `yaml:"name"`
+	Roles    []Role      `yaml:"roles"`
+	Apps     []deployApp `yaml:"apps"`
+	Services []Service   `yaml:"services"`
+}
+
+//deployApp describes a single app to be pushed
+type deployApp struct {
+	Name            string            `yaml:"name"`
+	Repo            string            `yaml:"repo"`
+	RepoRef         string            `yaml:"repo_ref"`         //branch, tag, or commit SHA to check out; defaults to HEAD. A commit SHA that isn't reachable from any advertised branch/tag tip can only be fetched if the git server has unadvertised-object fetching enabled (uploadpack.allowReachableSHA1InWant/allowAnySHA1InWant) - not the default on some self-hosted servers (Gitea, older GitLab CE, git-daemon, Bitbucket Server)
+	RepoAuth        string            `yaml:"repo_auth"`        //one of "ssh_key", "basic", "token"; empty for unauthenticated clones
+	RepoCredentials string            `yaml:"repo_credentials"` //"env:VAR" or "file:PATH" naming where the secret lives, never the secret itself
+	Path            string            `yaml:"path"`
+	Disk            string            `yaml:"disk"`
+	Memory          string            `yaml:"memory"`
+	Instances       string            `yaml:"instances"`
+	Hostname        string            `yaml:"hostname"`
+	Domain          string            `yaml:"domain"`
+	Buildpack       string            `yaml:"buildpack"`
+	Manifest        string            `yaml:"manifest"`
+	Services        []string          `yaml:"services"`
+	Env             map[string]string `yaml:"env"`
+	ServiceBroker   ServiceBroker     `yaml:"service_broker"`
+	ServiceAccess   []Service         `yaml:"service_access"`
+}
+
+//ServiceBroker describes an app that should be registered as a service broker
+type ServiceBroker struct {
+	Name     string `yaml:"name"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Url      string `yaml:"url"`
+}
+
+//Service describes a service instance to create, or a service/plan to enable access for
+type Service struct {
+	Name    string `yaml:"name"`
+	Service string `yaml:"service"`
+	Plan    string `yaml:"plan"`
+	Org     string `yaml:"org"`
+}
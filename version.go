@@ -0,0 +1,4 @@
+package main
+
+//VERSION of this plugin
+const VERSION = "0.1.0"